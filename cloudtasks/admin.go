@@ -0,0 +1,226 @@
+package cloudtasks
+
+import (
+	"context"
+	"fmt"
+
+	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
+	"google.golang.org/api/iterator"
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	iampb "google.golang.org/genproto/googleapis/iam/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TaskName is Queue に積まれている Task を指す Name を返す
+// taskID は projects/{PROJECT_ID}/locations/{LOCATION}/queues/{QUEUE_ID}/tasks/{TASK_ID} の {TASK_ID} の部分だけ指定する
+func (q *Queue) TaskName(taskID string) string {
+	return fmt.Sprintf("%s/tasks/%s", q.Parent(), taskID)
+}
+
+// LocationParent is Queue の一覧取得など、特定の Queue ではなく Location 単位の操作で使う Parent 文字列を返す
+func (q *Queue) LocationParent() string {
+	return fmt.Sprintf("projects/%s/locations/%s", q.ProjectID, q.Region)
+}
+
+// QueueAdmin is Queue 自体の作成・変更・削除や、積まれている Task の一覧取得・削除など、Queue のライフサイクル管理を行う
+// Service が Task の Enqueue だけを担当するのに対して、こちらは Queue 自体の管理を担当する
+type QueueAdmin struct {
+	taskClient *cloudtasks.Client
+}
+
+// NewQueueAdmin is QueueAdmin を作成する
+func NewQueueAdmin(ctx context.Context, taskClient *cloudtasks.Client) (*QueueAdmin, error) {
+	return &QueueAdmin{taskClient: taskClient}, nil
+}
+
+// CreateQueue is Queue を作成する
+// rateLimits, retryConfig はどちらも nil であれば Cloud Tasks のデフォルト値が使われる
+func (a *QueueAdmin) CreateQueue(ctx context.Context, queue *Queue, rateLimits *taskspb.RateLimits, retryConfig *taskspb.RetryConfig) (*taskspb.Queue, error) {
+	got, err := a.taskClient.CreateQueue(ctx, &taskspb.CreateQueueRequest{
+		Parent: queue.LocationParent(),
+		Queue: &taskspb.Queue{
+			Name:        queue.Parent(),
+			RateLimits:  rateLimits,
+			RetryConfig: retryConfig,
+		},
+	})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed CreateQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// UpdateQueue is Queue の RateLimits, RetryConfig を更新する
+// UpdateMask には rateLimits, retryConfig のうち non-nil なものだけを指定するので、指定していない方や AppEngineRoutingOverride などの他の設定は変更されない
+func (a *QueueAdmin) UpdateQueue(ctx context.Context, queue *Queue, rateLimits *taskspb.RateLimits, retryConfig *taskspb.RetryConfig) (*taskspb.Queue, error) {
+	var paths []string
+	if rateLimits != nil {
+		paths = append(paths, "rate_limits")
+	}
+	if retryConfig != nil {
+		paths = append(paths, "retry_config")
+	}
+	got, err := a.taskClient.UpdateQueue(ctx, &taskspb.UpdateQueueRequest{
+		Queue: &taskspb.Queue{
+			Name:        queue.Parent(),
+			RateLimits:  rateLimits,
+			RetryConfig: retryConfig,
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+	})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed UpdateQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// PauseQueue is Queue から Task が出ていかないようにする。Enqueue 自体は可能
+func (a *QueueAdmin) PauseQueue(ctx context.Context, queue *Queue) (*taskspb.Queue, error) {
+	got, err := a.taskClient.PauseQueue(ctx, &taskspb.PauseQueueRequest{Name: queue.Parent()})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed PauseQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// ResumeQueue is PauseQueue() で止めた Queue を再開する
+func (a *QueueAdmin) ResumeQueue(ctx context.Context, queue *Queue) (*taskspb.Queue, error) {
+	got, err := a.taskClient.ResumeQueue(ctx, &taskspb.ResumeQueueRequest{Name: queue.Parent()})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed ResumeQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// PurgeQueue is Queue に積まれている未実行の Task を全て削除する
+func (a *QueueAdmin) PurgeQueue(ctx context.Context, queue *Queue) (*taskspb.Queue, error) {
+	got, err := a.taskClient.PurgeQueue(ctx, &taskspb.PurgeQueueRequest{Name: queue.Parent()})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed PurgeQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// DeleteQueue is Queue を削除する
+func (a *QueueAdmin) DeleteQueue(ctx context.Context, queue *Queue) error {
+	err := a.taskClient.DeleteQueue(ctx, &taskspb.DeleteQueueRequest{Name: queue.Parent()})
+	if err != nil {
+		return convertQueueAdminError(fmt.Sprintf("failed DeleteQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return nil
+}
+
+// GetQueue is Queue の設定を取得する
+func (a *QueueAdmin) GetQueue(ctx context.Context, queue *Queue) (*taskspb.Queue, error) {
+	got, err := a.taskClient.GetQueue(ctx, &taskspb.GetQueueRequest{Name: queue.Parent()})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed GetQueue. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// ListQueues is Location 配下の Queue を一覧する
+func (a *QueueAdmin) ListQueues(ctx context.Context, projectID string, region string) ([]*taskspb.Queue, error) {
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	it := a.taskClient.ListQueues(ctx, &taskspb.ListQueuesRequest{Parent: parent})
+	var queues []*taskspb.Queue
+	for {
+		q, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, convertQueueAdminError(fmt.Sprintf("failed ListQueues. parent=%s", parent), map[string]interface{}{"parent": parent}, err)
+		}
+		queues = append(queues, q)
+	}
+	return queues, nil
+}
+
+// ListTasks is Queue に積まれている Task を一覧する
+func (a *QueueAdmin) ListTasks(ctx context.Context, queue *Queue) ([]*taskspb.Task, error) {
+	it := a.taskClient.ListTasks(ctx, &taskspb.ListTasksRequest{Parent: queue.Parent()})
+	var tasks []*taskspb.Task
+	for {
+		task, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, convertQueueAdminError(fmt.Sprintf("failed ListTasks. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// GetTask is Queue に積まれている Task を1件取得する
+// taskID は TaskName() に渡すのと同じ {TASK_ID} 部分だけを指定する
+func (a *QueueAdmin) GetTask(ctx context.Context, queue *Queue, taskID string) (*taskspb.Task, error) {
+	name := queue.TaskName(taskID)
+	got, err := a.taskClient.GetTask(ctx, &taskspb.GetTaskRequest{Name: name})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed GetTask. task=%s", name), map[string]interface{}{"task": name}, err)
+	}
+	return got, nil
+}
+
+// DeleteTask is Queue に積まれている Task を1件削除する
+func (a *QueueAdmin) DeleteTask(ctx context.Context, queue *Queue, taskID string) error {
+	name := queue.TaskName(taskID)
+	err := a.taskClient.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Name: name})
+	if err != nil {
+		return convertQueueAdminError(fmt.Sprintf("failed DeleteTask. task=%s", name), map[string]interface{}{"task": name}, err)
+	}
+	return nil
+}
+
+// GetIamPolicy is Queue の IAM Policy を取得する
+func (a *QueueAdmin) GetIamPolicy(ctx context.Context, queue *Queue) (*iampb.Policy, error) {
+	got, err := a.taskClient.GetIamPolicy(ctx, &iampb.GetIamPolicyRequest{Resource: queue.Parent()})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed GetIamPolicy. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// SetIamPolicy is Queue の IAM Policy を設定する
+func (a *QueueAdmin) SetIamPolicy(ctx context.Context, queue *Queue, policy *iampb.Policy) (*iampb.Policy, error) {
+	got, err := a.taskClient.SetIamPolicy(ctx, &iampb.SetIamPolicyRequest{Resource: queue.Parent(), Policy: policy})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed SetIamPolicy. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got, nil
+}
+
+// TestIamPermissions is 呼び出し元が Queue に対して permissions を持っているかを確認する
+func (a *QueueAdmin) TestIamPermissions(ctx context.Context, queue *Queue, permissions []string) ([]string, error) {
+	got, err := a.taskClient.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{Resource: queue.Parent(), Permissions: permissions})
+	if err != nil {
+		return nil, convertQueueAdminError(fmt.Sprintf("failed TestIamPermissions. queue=%s", queue.Parent()), map[string]interface{}{"queue": queue.Parent()}, err)
+	}
+	return got.GetPermissions(), nil
+}
+
+// convertQueueAdminError is gRPC の Status Code を gcpbox の Error taxonomy にマッピングする
+// CreateTask() が AlreadyExists をハンドリングしているのと同じ方針
+func convertQueueAdminError(message string, kv map[string]interface{}, err error) error {
+	sts, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch sts.Code() {
+	case codes.AlreadyExists:
+		return NewErrAlreadyExists(message, kv, err)
+	case codes.NotFound:
+		return NewErrNotFound(message, kv, err)
+	case codes.InvalidArgument:
+		return NewErrInvalidArgument(message, kv, err)
+	case codes.PermissionDenied:
+		return NewErrPermissionDenied(message, kv, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"testing"
+
+	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
+	tasksbox "github.com/sinmetalcraft/gcpbox/cloudtasks"
+)
+
+func TestQueueAdmin_GetQueue(t *testing.T) {
+	ctx := context.Background()
+
+	a := newQueueAdmin(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+
+	got, err := a.GetQueue(ctx, queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetName() != queue.Parent() {
+		t.Errorf("unexpected queue name. got=%s, want=%s", got.GetName(), queue.Parent())
+	}
+}
+
+func TestQueueAdmin_ListTasks(t *testing.T) {
+	ctx := context.Background()
+
+	a := newQueueAdmin(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+
+	if _, err := a.ListTasks(ctx, queue); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newQueueAdmin(t *testing.T) *tasksbox.QueueAdmin {
+	ctx := context.Background()
+
+	taskClient, err := cloudtasks.NewClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := tasksbox.NewQueueAdmin(ctx, taskClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
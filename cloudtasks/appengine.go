@@ -0,0 +1,280 @@
+package cloudtasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/xerrors"
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AppEngineRouting is Task を振り分ける App Engine の Service/Version/Instance を指定する
+// 空文字列を指定した field は App Engine 側のデフォルトの振り分けルールに従う
+type AppEngineRouting struct {
+	Service  string
+	Version  string
+	Instance string
+}
+
+func (r *AppEngineRouting) toProto() *taskspb.AppEngineRouting {
+	if r == nil {
+		return nil
+	}
+	return &taskspb.AppEngineRouting{
+		Service:  r.Service,
+		Version:  r.Version,
+		Instance: r.Instance,
+	}
+}
+
+// CreateAppEngineTask is App Engine 向けの Task を作る一番 Primitive なやつ
+// CreateTask() の App Engine 版
+func (s *Service) CreateAppEngineTask(ctx context.Context, queue *Queue, taskName string, req *taskspb.AppEngineHttpRequest, scheduledTime time.Time, deadline time.Duration, ops ...CreateTaskOptions) (*taskspb.Task, error) {
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
+	taskReq := &taskspb.CreateTaskRequest{
+		Parent: queue.Parent(),
+		Task: &taskspb.Task{
+			MessageType: &taskspb.Task_AppEngineHttpRequest{
+				AppEngineHttpRequest: req,
+			},
+		},
+	}
+	if len(taskName) > 0 {
+		taskReq.GetTask().Name = queue.TaskName(taskName)
+	}
+	if !scheduledTime.IsZero() {
+		stpb, err := ptypes.TimestampProto(scheduledTime)
+		if err != nil {
+			return nil, NewErrInvalidArgument("invalid ScheduleTime", map[string]interface{}{"ScheduledTime": scheduledTime}, err)
+		}
+		taskReq.Task.ScheduleTime = stpb
+	}
+	if deadline != 0 {
+		taskReq.Task.DispatchDeadline = ptypes.DurationProto(deadline)
+	}
+	task, err := s.taskClient.CreateTask(ctx, taskReq)
+	if err != nil {
+		sts, ok := status.FromError(err)
+		if ok {
+			if sts.Code() == codes.AlreadyExists {
+				if opt.ignoreAlreadyExists {
+					return taskReq.GetTask(), nil
+				}
+				return nil, NewErrAlreadyExists(fmt.Sprintf("%s is already exists.", taskReq.GetTask().Name), map[string]interface{}{"taskName": taskReq.GetTask().Name}, err)
+			}
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// AppEngineJsonPostTask is App Engine 向けの BodyにJsonを入れるTask
+// OIDC 用の Service Account を用意しなくても、App Engine Standard 上のサービスに Task を届けられる
+type AppEngineJsonPostTask struct {
+	// Task を振り分ける App Engine の Service/Version/Instance
+	// optional。未指定の場合は Queue の設定に従う
+	Routing *AppEngineRouting
+
+	// Task が到達する Handler の Path
+	// /foo/bar のような形式
+	RelativeURI string
+
+	// ScheduledTime is estimated time of arrival
+	ScheduledTime time.Time
+
+	// HandlerのDeadline
+	// default は 10min 最長は 30min
+	Deadline time.Duration
+
+	// Task Body
+	// 中で JSON に変換する
+	Body interface{}
+
+	// Name is Task Name
+	// optional
+	Name string
+
+	// DedupBy is WithDedupHash() と一緒に使う
+	// optional
+	DedupBy interface{}
+}
+
+// CreateAppEngineJsonPostTask is App Engine 向けの BodyにJsonを入れるTaskを作る
+func (s *Service) CreateAppEngineJsonPostTask(ctx context.Context, queue *Queue, task *AppEngineJsonPostTask, ops ...CreateTaskOptions) (string, error) {
+	body, err := json.Marshal(task.Body)
+	if err != nil {
+		return "", xerrors.Errorf("failed json.Marshal(). body=%+v : %w", task.Body, err)
+	}
+
+	taskName := task.Name
+	if len(taskName) == 0 {
+		opt := createTaskOptions{}
+		for _, o := range ops {
+			o(&opt)
+		}
+		if opt.dedupHashEnabled {
+			payload, err := dedupPayload(task.DedupBy, body)
+			if err != nil {
+				return "", xerrors.Errorf("failed dedupPayload(). queue=%+v : %w", queue, err)
+			}
+			taskName = dedupTaskName(queue.Parent(), taskspb.HttpMethod_POST.String(), task.RelativeURI, payload, opt.dedupHashBucket)
+		}
+	}
+
+	got, err := s.CreateAppEngineTask(ctx, queue, taskName, &taskspb.AppEngineHttpRequest{
+		HttpMethod:       taskspb.HttpMethod_POST,
+		AppEngineRouting: task.Routing.toProto(),
+		RelativeUri:      task.RelativeURI,
+		Headers:          map[string]string{"Content-Type": "application/json"},
+		Body:             body,
+	}, task.ScheduledTime, task.Deadline, ops...)
+	if err != nil {
+		return "", xerrors.Errorf("failed CreateAppEngineJsonPostTask(). queue=%+v, body=%+v : %w", queue, task.Body, err)
+	}
+	return got.Name, nil
+}
+
+// CreateAppEngineJsonPostTaskMulti is Queue に AppEngineJsonPostTask を複数作成する
+// WithMaxConcurrency() で同時実行数を、WithRetry() で retryable な Code を受け取った時の再試行方法を指定できる
+func (s *Service) CreateAppEngineJsonPostTaskMulti(ctx context.Context, queue *Queue, tasks []*AppEngineJsonPostTask, ops ...CreateTaskOptions) ([]string, error) {
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
+	results := make([]string, len(tasks))
+	merr := MultiError{}
+	sem := make(chan struct{}, opt.maxConcurrencyOrDefault())
+	wg := &sync.WaitGroup{}
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task *AppEngineJsonPostTask) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			tn, attempts, err := runWithRetry(ctx, opt.retryPolicy, func() (string, error) {
+				return s.CreateAppEngineJsonPostTask(ctx, queue, task, ops...)
+			})
+			if err != nil {
+				appErr := &Error{}
+				if xerrors.As(err, &appErr) && appErr.Code == ErrAlreadyExists.Code {
+					appErr.KV["index"] = i
+					appErr.KV["attempts"] = attempts
+					merr.Append(appErr)
+					return
+				}
+				merr.Append(NewErrCreateMultiTask("failed CreateAppEngineJsonPostTask", map[string]interface{}{"index": i, "taskName": task.Name, "URI": task.RelativeURI, "attempts": attempts}, err))
+			}
+			results[i] = tn
+		}(i, task)
+	}
+	wg.Wait()
+	return results, merr.ErrorOrNil()
+}
+
+// AppEngineGetTask is App Engine 向けの Get Request 用の Task
+type AppEngineGetTask struct {
+	// Task を振り分ける App Engine の Service/Version/Instance
+	// optional。未指定の場合は Queue の設定に従う
+	Routing *AppEngineRouting
+
+	// Task Request の Header
+	Headers map[string]string
+
+	// Task が到達する Handler の Path
+	RelativeURI string
+
+	// ScheduledTime is estimated time of arrival
+	ScheduledTime time.Time
+
+	// HandlerのDeadline
+	// default は 10min 最長は 30min
+	Deadline time.Duration
+
+	// Name is Task Name
+	// optional
+	Name string
+
+	// DedupBy is WithDedupHash() と一緒に使う
+	// optional
+	DedupBy interface{}
+}
+
+// CreateAppEngineGetTask is App Engine 向けの Get Request 用の Task を作る
+func (s *Service) CreateAppEngineGetTask(ctx context.Context, queue *Queue, task *AppEngineGetTask, ops ...CreateTaskOptions) (string, error) {
+	taskName := task.Name
+	if len(taskName) == 0 {
+		opt := createTaskOptions{}
+		for _, o := range ops {
+			o(&opt)
+		}
+		if opt.dedupHashEnabled {
+			payload, err := dedupPayload(task.DedupBy, nil)
+			if err != nil {
+				return "", xerrors.Errorf("failed dedupPayload(). queue=%+v : %w", queue, err)
+			}
+			taskName = dedupTaskName(queue.Parent(), taskspb.HttpMethod_GET.String(), task.RelativeURI, payload, opt.dedupHashBucket)
+		}
+	}
+
+	got, err := s.CreateAppEngineTask(ctx, queue, taskName, &taskspb.AppEngineHttpRequest{
+		HttpMethod:       taskspb.HttpMethod_GET,
+		AppEngineRouting: task.Routing.toProto(),
+		RelativeUri:      task.RelativeURI,
+		Headers:          task.Headers,
+	}, task.ScheduledTime, task.Deadline, ops...)
+	if err != nil {
+		return "", xerrors.Errorf("failed CreateAppEngineGetTask(). queue=%+v, url=%s : %w", queue, task.RelativeURI, err)
+	}
+	return got.Name, nil
+}
+
+// CreateAppEngineGetTaskMulti is Queue に AppEngineGetTask を複数作成する
+// WithMaxConcurrency() で同時実行数を、WithRetry() で retryable な Code を受け取った時の再試行方法を指定できる
+func (s *Service) CreateAppEngineGetTaskMulti(ctx context.Context, queue *Queue, tasks []*AppEngineGetTask, ops ...CreateTaskOptions) ([]string, error) {
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
+	results := make([]string, len(tasks))
+	merr := MultiError{}
+	sem := make(chan struct{}, opt.maxConcurrencyOrDefault())
+	wg := &sync.WaitGroup{}
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task *AppEngineGetTask) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			tn, attempts, err := runWithRetry(ctx, opt.retryPolicy, func() (string, error) {
+				return s.CreateAppEngineGetTask(ctx, queue, task, ops...)
+			})
+			if err != nil {
+				appErr := &Error{}
+				if xerrors.As(err, &appErr) && appErr.Code == ErrAlreadyExists.Code {
+					appErr.KV["index"] = i
+					appErr.KV["attempts"] = attempts
+					merr.Append(appErr)
+					return
+				}
+				merr.Append(NewErrCreateMultiTask("failed CreateAppEngineGetTask", map[string]interface{}{"index": i, "taskName": task.Name, "URI": task.RelativeURI, "attempts": attempts}, err))
+			}
+			results[i] = tn
+		}(i, task)
+	}
+	wg.Wait()
+	return results, merr.ErrorOrNil()
+}
@@ -0,0 +1,61 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tasksbox "github.com/sinmetalcraft/gcpbox/cloudtasks"
+)
+
+func TestService_CreateAppEngineJsonPostTask(t *testing.T) {
+	ctx := context.Background()
+
+	s := newService(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+	type Body struct {
+		Content string
+	}
+
+	taskName, err := s.CreateAppEngineJsonPostTask(ctx, queue, &tasksbox.AppEngineJsonPostTask{
+		RelativeURI: "/cloudtasks/run/json-post-task",
+		Deadline:    30 * time.Minute,
+		Body: &Body{
+			Content: "Hello AppEngineJsonPostTask",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskName) < 1 {
+		t.Error("task name is empty")
+	}
+}
+
+func TestService_CreateAppEngineGetTask(t *testing.T) {
+	ctx := context.Background()
+
+	s := newService(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+
+	taskName, err := s.CreateAppEngineGetTask(ctx, queue, &tasksbox.AppEngineGetTask{
+		RelativeURI: "/cloudtasks/run/json-post-task",
+		Deadline:    30 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskName) < 1 {
+		t.Error("task name is empty")
+	}
+}
@@ -0,0 +1,110 @@
+package cloudtasks
+
+import (
+	"context"
+
+	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+)
+
+// AuthMode is Task を投げる時にどの認証方式を使うかを指定する
+type AuthMode int
+
+const (
+	// AuthUnspecified is 未指定。Service の defaultAuth に従う
+	AuthUnspecified AuthMode = iota
+
+	// AuthOIDC is OIDC Token を使う。Cloud Run, Cloud Functions, IAP など IAM で守られている Handler 向け
+	AuthOIDC
+
+	// AuthOAuth2 is OAuth2 Access Token を使う。googleapis.com など Google API を直接叩く Handler 向け
+	AuthOAuth2
+
+	// AuthNone is 認証情報を付けない。外部に公開している Handler 向け
+	AuthNone
+)
+
+// defaultOAuth2Scope is OAuth2TokenConfig.Scope を指定しなかった場合に使う Scope
+const defaultOAuth2Scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// OAuth2TokenConfig is AuthOAuth2 を使う時に Token を発行する Service Account と Scope
+type OAuth2TokenConfig struct {
+	// ServiceAccountEmail is optional。未指定の場合は Service に設定されている物を使う
+	ServiceAccountEmail string
+
+	// Scope is optional。未指定の場合は defaultOAuth2Scope を使う
+	Scope string
+}
+
+// ServiceOptions is NewServiceWithOptions() の挙動を変更する Option
+type ServiceOptions func(*serviceOptions)
+
+type serviceOptions struct {
+	defaultAuth AuthMode
+}
+
+// WithDefaultAuth is JsonPostTask.Auth, GetTask.Auth を個別に指定しなかった場合に使う AuthMode を指定する
+// NewService() で作成した Service は今まで通り AuthOIDC が使われる
+func WithDefaultAuth(mode AuthMode) ServiceOptions {
+	return func(o *serviceOptions) {
+		o.defaultAuth = mode
+	}
+}
+
+// NewServiceWithOptions is Option 付きで Service を作成する
+// Task 個別に Auth を指定しなかった場合、WithDefaultAuth() で指定した AuthMode が使われる
+func NewServiceWithOptions(ctx context.Context, taskClient *cloudtasks.Client, serviceAccountEmail string, ops ...ServiceOptions) (*Service, error) {
+	opt := serviceOptions{defaultAuth: AuthOIDC}
+	for _, o := range ops {
+		o(&opt)
+	}
+	return &Service{
+		taskClient:          taskClient,
+		serviceAccountEmail: serviceAccountEmail,
+		defaultAuth:         opt.defaultAuth,
+	}, nil
+}
+
+// setAuthorizationHeader is Task 個別の AuthMode (mode) と OAuth2Token 設定から、req.AuthorizationHeader を設定する
+// mode が AuthUnspecified の場合は s.defaultAuth (未設定なら AuthOIDC) を使う
+//
+// HttpRequest.AuthorizationHeader は taskspb 内の unexported interface なので、外部の関数から返り値として返すことができない
+// そのため CreateJsonPostTask/CreateGetTask が組み立てた req を直接書き換える形にしている
+func (s *Service) setAuthorizationHeader(req *taskspb.HttpRequest, mode AuthMode, audience string, oauth2 *OAuth2TokenConfig) {
+	effective := mode
+	if effective == AuthUnspecified {
+		effective = s.defaultAuth
+		if effective == AuthUnspecified {
+			effective = AuthOIDC
+		}
+	}
+
+	switch effective {
+	case AuthOAuth2:
+		email := s.serviceAccountEmail
+		scope := defaultOAuth2Scope
+		if oauth2 != nil {
+			if len(oauth2.ServiceAccountEmail) > 0 {
+				email = oauth2.ServiceAccountEmail
+			}
+			if len(oauth2.Scope) > 0 {
+				scope = oauth2.Scope
+			}
+		}
+		req.AuthorizationHeader = &taskspb.HttpRequest_OauthToken{
+			OauthToken: &taskspb.OAuthToken{
+				ServiceAccountEmail: email,
+				Scope:               scope,
+			},
+		}
+	case AuthNone:
+		req.AuthorizationHeader = nil
+	default: // AuthOIDC
+		req.AuthorizationHeader = &taskspb.HttpRequest_OidcToken{
+			OidcToken: &taskspb.OidcToken{
+				ServiceAccountEmail: s.serviceAccountEmail,
+				Audience:            audience,
+			},
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
+	tasksbox "github.com/sinmetalcraft/gcpbox/cloudtasks"
+)
+
+func TestService_CreateJsonPostTask_WithOAuth2Token(t *testing.T) {
+	ctx := context.Background()
+
+	taskClient, err := cloudtasks.NewClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := tasksbox.NewServiceWithOptions(ctx, taskClient, "sinmetal-ci@appspot.gserviceaccount.com", tasksbox.WithDefaultAuth(tasksbox.AuthOAuth2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+	type Body struct {
+		Content string
+	}
+
+	taskName, err := s.CreateJsonPostTask(ctx, queue, &tasksbox.JsonPostTask{
+		RelativeURI: "https://www.googleapis.com/upload/storage/v1/b",
+		Deadline:    30 * time.Minute,
+		Body: &Body{
+			Content: "Hello OAuth2Token",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskName) < 1 {
+		t.Error("task name is empty")
+	}
+}
+
+func TestService_CreateGetTask_WithAuthNone(t *testing.T) {
+	ctx := context.Background()
+
+	s := newService(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+
+	taskName, err := s.CreateGetTask(ctx, queue, &tasksbox.GetTask{
+		RelativeURI: "https://gcpboxtest-73zry4yfvq-an.a.run.app/cloudtasks/run/json-post-task",
+		Deadline:    30 * time.Minute,
+		Auth:        tasksbox.AuthNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskName) < 1 {
+		t.Error("task name is empty")
+	}
+}
@@ -0,0 +1,64 @@
+package cloudtasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// WithDedupHash is Queue, HTTP Method, URL, Body (または DedupBy) と bucket から決定的な Task Name を生成し、Task の重複排除を行う Option
+// Name を自分で組み立てなくても、同じ内容の Task を同じ bucket 内で複数回 Enqueue した場合、2 件目以降は ErrAlreadyExists になる
+//
+// bucket には日次バッチの実行日や、何らかの time window を表す key を渡すことを想定している
+// task.Name が指定されている場合はそちらが優先され、この Option は無視される
+func WithDedupHash(bucket string) CreateTaskOptions {
+	return func(o *createTaskOptions) {
+		o.dedupHashEnabled = true
+		o.dedupHashBucket = bucket
+	}
+}
+
+// dedupTaskName is FNV-64a hash から Cloud Tasks の Task Name 制約 (英数字とハイフンのみ、500文字以内) を満たす Name を作る
+// parent は Queue.Parent() の戻り値を渡す。InMemoryQueue のように Queue 自体が存在しない場合は空文字列で良い
+func dedupTaskName(parent string, method string, url string, payload []byte, bucket string) string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%s\x00", parent, method, url)
+	h.Write(payload)
+	_, _ = fmt.Fprintf(h, "\x00%s", bucket)
+
+	name := fmt.Sprintf("dedup-%x-%s", h.Sum64(), sanitizeTaskNamePart(bucket))
+	if len(name) > 500 {
+		name = name[:500]
+	}
+	return name
+}
+
+// sanitizeTaskNamePart is Cloud Tasks の Task Name に使えない文字をハイフンに置き換える
+func sanitizeTaskNamePart(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// dedupPayload is DedupBy が設定されていればそれを、無ければ body をそのまま hash の対象にする
+// DedupBy は Body とは違い ScheduledTime を揺らすためだけに使うような nonce を含めたくない場合に使う
+func dedupPayload(dedupBy interface{}, body []byte) ([]byte, error) {
+	if dedupBy == nil {
+		return body, nil
+	}
+	b, err := json.Marshal(dedupBy)
+	if err != nil {
+		return nil, xerrors.Errorf("failed json.Marshal(). dedupBy=%+v : %w", dedupBy, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,56 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tasksbox "github.com/sinmetalcraft/gcpbox/cloudtasks"
+)
+
+func TestService_CreateJsonPostTask_WithDedupHash(t *testing.T) {
+	ctx := context.Background()
+
+	s := newService(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+	type Body struct {
+		Content string
+	}
+
+	const runHandlerURI = "https://gcpboxtest-73zry4yfvq-an.a.run.app/cloudtasks/run/json-post-task"
+	newTask := func() *tasksbox.JsonPostTask {
+		return &tasksbox.JsonPostTask{
+			Audience:    "",
+			RelativeURI: runHandlerURI,
+			Deadline:    30 * time.Minute,
+			Body: &Body{
+				Content: "Hello DedupHash",
+			},
+		}
+	}
+
+	// 同じ window (bucket) に対して同じ内容の Task を2回 Enqueue すると、2回目は同じ Task Name になり AlreadyExists になる
+	bucket := time.Now().Format("20060102-150405")
+
+	n1, err := s.CreateJsonPostTask(ctx, queue, newTask(), tasksbox.WithDedupHash(bucket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n1) < 1 {
+		t.Error("task name is empty")
+	}
+
+	_, err = s.CreateJsonPostTask(ctx, queue, newTask(), tasksbox.WithDedupHash(bucket))
+	if err == nil {
+		t.Fatal("expected AlreadyExists error but got nil")
+	}
+	if !strings.Contains(strings.ToLower(err.Error()), "already exists") {
+		t.Errorf("expected AlreadyExists error, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package cloudtasks
+
+// CreateTaskOptions is CreateTask, CreateJsonPostTask, CreateGetTask などの挙動を変更する Option
+type CreateTaskOptions func(*createTaskOptions)
+
+type createTaskOptions struct {
+	ignoreAlreadyExists bool
+
+	dedupHashEnabled bool
+	dedupHashBucket  string
+
+	maxConcurrency int
+	retryPolicy    *RetryPolicy
+}
+
+// WithIgnoreAlreadyExists is 同名の Task が既に存在する場合に ErrAlreadyExists を返さず成功扱いにする
+func WithIgnoreAlreadyExists() CreateTaskOptions {
+	return func(o *createTaskOptions) {
+		o.ignoreAlreadyExists = true
+	}
+}
@@ -0,0 +1,322 @@
+package cloudtasks
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+)
+
+// ProcessFunc is InMemoryQueue が Task を実行する時に呼び出す関数
+// 本物の Cloud Tasks が Handler に対して行う HTTP Request の代わりにこれが呼ばれる
+type ProcessFunc func(ctx context.Context, method string, url string, headers map[string]string, body []byte) error
+
+// TaskQueue is Task を Enqueue するための interface
+// Service (本物の Cloud Tasks) と InMemoryQueue (ローカル開発・テスト用) の両方がこれを満たすので、呼び出し側は実行環境を気にせず同じコードを書ける
+//
+// task.Name や WithDedupHash() による重複排除は両方の実装で同じように働く
+// WithMaxConcurrency(), WithRetry() は ...Multi 系のメソッドにのみ効く Option なので、Enqueue/EnqueueGet ではどちらの実装でも無視される
+type TaskQueue interface {
+	// Enqueue is JsonPostTask を Queue に積む
+	Enqueue(ctx context.Context, task *JsonPostTask, ops ...CreateTaskOptions) (string, error)
+
+	// EnqueueGet is GetTask を Queue に積む
+	EnqueueGet(ctx context.Context, task *GetTask, ops ...CreateTaskOptions) (string, error)
+}
+
+// Queue is s と queue を束ねた TaskQueue を返す
+// GCP 上で動かす時はこちらを、ローカル開発やテストでは NewInMemoryQueue を使う
+func (s *Service) Queue(queue *Queue) TaskQueue {
+	return &serviceQueue{s: s, queue: queue}
+}
+
+// serviceQueue is Service に特定の Queue を紐付けた TaskQueue 実装
+type serviceQueue struct {
+	s     *Service
+	queue *Queue
+}
+
+func (sq *serviceQueue) Enqueue(ctx context.Context, task *JsonPostTask, ops ...CreateTaskOptions) (string, error) {
+	return sq.s.CreateJsonPostTask(ctx, sq.queue, task, ops...)
+}
+
+func (sq *serviceQueue) EnqueueGet(ctx context.Context, task *GetTask, ops ...CreateTaskOptions) (string, error) {
+	return sq.s.CreateGetTask(ctx, sq.queue, task, ops...)
+}
+
+// pendingTask is InMemoryQueue の中で処理待ちになっている Task
+type pendingTask struct {
+	method        string
+	url           string
+	headers       map[string]string
+	body          []byte
+	scheduledTime time.Time
+	deadline      time.Duration
+}
+
+// pendingTaskHeap is container/heap の実装。ScheduledTime が早い Task ほど先頭に来る
+type pendingTaskHeap []*pendingTask
+
+func (h pendingTaskHeap) Len() int            { return len(h) }
+func (h pendingTaskHeap) Less(i, j int) bool  { return h[i].scheduledTime.Before(h[j].scheduledTime) }
+func (h pendingTaskHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *pendingTaskHeap) Push(x interface{}) { *h = append(*h, x.(*pendingTask)) }
+func (h *pendingTaskHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// InMemoryQueue is TaskQueue の in-memory 実装
+// 本物の Cloud Tasks を使わず ProcessFunc を直接呼び出すので、ローカル開発や単体テストで Service の代わりに使える
+//
+// workers 個の goroutine が ScheduledTime の早い順に Task を取り出して処理する
+// pkgsite-metrics の queue パッケージと同様の考え方
+type InMemoryQueue struct {
+	process ProcessFunc
+	onError func(err error, method string, url string)
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  pendingTaskHeap
+	names  map[string]struct{}
+	closed bool
+
+	wg sync.WaitGroup
+}
+
+// InMemoryQueueOptions is NewInMemoryQueue() の挙動を変更する Option
+type InMemoryQueueOptions func(*inMemoryQueueOptions)
+
+type inMemoryQueueOptions struct {
+	onError func(err error, method string, url string)
+}
+
+// WithOnError is ProcessFunc が error を返した時に呼び出す関数を指定する
+// 本物の Cloud Tasks と違い InMemoryQueue は失敗した Task を自動では retry しないので、指定しない場合 ProcessFunc の error は握りつぶされて何も分からなくなる
+// テストで Handler が失敗したことを検知したい場合などに使う
+func WithOnError(f func(err error, method string, url string)) InMemoryQueueOptions {
+	return func(o *inMemoryQueueOptions) {
+		o.onError = f
+	}
+}
+
+// NewInMemoryQueue is InMemoryQueue を作成する
+// workers は同時に Task を処理する goroutine の数。1 未満を渡した場合は 1 として扱う
+func NewInMemoryQueue(workers int, process ProcessFunc, ops ...InMemoryQueueOptions) *InMemoryQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	opt := inMemoryQueueOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+	q := &InMemoryQueue{
+		process: process,
+		onError: opt.onError,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+	return q
+}
+
+// Enqueue is JsonPostTask を積む
+// InMemoryQueue は実際には Cloud Tasks に触らないので Audience は無視されるが、task.Name や WithDedupHash() による
+// 重複排除は Service.CreateJsonPostTask() と同じように働く。既に同じ Name の Task が積まれている場合は
+// WithIgnoreAlreadyExists() が指定されていればそのまま成功、なければ ErrAlreadyExists を返す
+func (q *InMemoryQueue) Enqueue(ctx context.Context, task *JsonPostTask, ops ...CreateTaskOptions) (string, error) {
+	body, err := json.Marshal(task.Body)
+	if err != nil {
+		return "", xerrors.Errorf("failed json.Marshal(). body=%+v : %w", task.Body, err)
+	}
+
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
+	taskName := task.Name
+	if len(taskName) == 0 && opt.dedupHashEnabled {
+		payload, err := dedupPayload(task.DedupBy, body)
+		if err != nil {
+			return "", xerrors.Errorf("failed dedupPayload(). url=%s : %w", task.RelativeURI, err)
+		}
+		taskName = dedupTaskName("", taskspb.HttpMethod_POST.String(), task.RelativeURI, payload, opt.dedupHashBucket)
+	}
+
+	if len(taskName) > 0 && !q.reserveName(taskName) {
+		if opt.ignoreAlreadyExists {
+			return taskName, nil
+		}
+		return "", NewErrAlreadyExists(fmt.Sprintf("%s is already exists.", taskName), map[string]interface{}{"taskName": taskName}, nil)
+	}
+
+	if err := q.push(&pendingTask{
+		method:        "POST",
+		url:           task.RelativeURI,
+		headers:       map[string]string{"Content-Type": "application/json"},
+		body:          body,
+		scheduledTime: task.ScheduledTime,
+		deadline:      task.Deadline,
+	}); err != nil {
+		return "", err
+	}
+	return taskName, nil
+}
+
+// EnqueueGet is GetTask を積む
+// 重複排除の挙動は Enqueue() と同様 (Service.CreateGetTask() 相当)
+func (q *InMemoryQueue) EnqueueGet(ctx context.Context, task *GetTask, ops ...CreateTaskOptions) (string, error) {
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
+	taskName := task.Name
+	if len(taskName) == 0 && opt.dedupHashEnabled {
+		payload, err := dedupPayload(task.DedupBy, nil)
+		if err != nil {
+			return "", xerrors.Errorf("failed dedupPayload(). url=%s : %w", task.RelativeURI, err)
+		}
+		taskName = dedupTaskName("", taskspb.HttpMethod_GET.String(), task.RelativeURI, payload, opt.dedupHashBucket)
+	}
+
+	if len(taskName) > 0 && !q.reserveName(taskName) {
+		if opt.ignoreAlreadyExists {
+			return taskName, nil
+		}
+		return "", NewErrAlreadyExists(fmt.Sprintf("%s is already exists.", taskName), map[string]interface{}{"taskName": taskName}, nil)
+	}
+
+	if err := q.push(&pendingTask{
+		method:        "GET",
+		url:           task.RelativeURI,
+		headers:       task.Headers,
+		scheduledTime: task.ScheduledTime,
+		deadline:      task.Deadline,
+	}); err != nil {
+		return "", err
+	}
+	return taskName, nil
+}
+
+// reserveName is taskName を初めて見た場合は記録して true を返し、既に記録済みなら false を返す
+// taskName が空の場合は重複排除の対象外なので常に true を返す
+func (q *InMemoryQueue) reserveName(taskName string) bool {
+	if len(taskName) == 0 {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.names == nil {
+		q.names = map[string]struct{}{}
+	}
+	if _, ok := q.names[taskName]; ok {
+		return false
+	}
+	q.names[taskName] = struct{}{}
+	return true
+}
+
+// push is t を積む。Close 済みの場合は積まずに error を返す
+func (q *InMemoryQueue) push(t *pendingTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return xerrors.New("InMemoryQueue is already closed")
+	}
+	heap.Push(&q.items, t)
+	q.cond.Broadcast()
+	return nil
+}
+
+// worker is ScheduledTime が来た Task を取り出し続け、ProcessFunc を呼び出す
+// ProcessFunc が返した error は WithOnError() で指定した関数があればそこに渡す。無ければ何もしない
+func (q *InMemoryQueue) worker() {
+	defer q.wg.Done()
+	for {
+		t, ok := q.next()
+		if !ok {
+			return
+		}
+		ctx := context.Background()
+		if t.deadline > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.deadline)
+			q.reportError(q.process(ctx, t.method, t.url, t.headers, t.body), t.method, t.url)
+			cancel()
+			continue
+		}
+		q.reportError(q.process(ctx, t.method, t.url, t.headers, t.body), t.method, t.url)
+	}
+}
+
+// reportError is err が nil でなければ onError に通知する
+func (q *InMemoryQueue) reportError(err error, method string, url string) {
+	if err == nil || q.onError == nil {
+		return
+	}
+	q.onError(err, method, url)
+}
+
+// next is 次に実行すべき Task を取り出す
+// ScheduledTime がまだ先の Task しか無い場合はその時刻まで待つ
+// Close 済みで積まれている Task も無くなったら false を返す
+func (q *InMemoryQueue) next() (*pendingTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if len(q.items) == 0 {
+			if q.closed {
+				return nil, false
+			}
+			q.cond.Wait()
+			continue
+		}
+		t := q.items[0]
+		if t.scheduledTime.IsZero() || !t.scheduledTime.After(time.Now()) {
+			heap.Pop(&q.items)
+			return t, true
+		}
+		wait := time.Until(t.scheduledTime)
+		timer := time.AfterFunc(wait, func() {
+			q.mu.Lock()
+			q.cond.Broadcast()
+			q.mu.Unlock()
+		})
+		q.cond.Wait()
+		timer.Stop()
+	}
+}
+
+// Close is 新規の Task の受付を止め、積まれている Task を全て処理し終えるまで待つ
+// graceful shutdown 用。ctx が先にタイムアウトした場合は ctx.Err() を返す
+func (q *InMemoryQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
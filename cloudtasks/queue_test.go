@@ -0,0 +1,191 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tasksbox "github.com/sinmetalcraft/gcpbox/cloudtasks"
+)
+
+func TestInMemoryQueue_Enqueue(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var gotURL string
+	var gotBody []byte
+
+	q := tasksbox.NewInMemoryQueue(2, func(ctx context.Context, method, url string, headers map[string]string, body []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		gotURL = url
+		gotBody = body
+		return nil
+	})
+
+	type Body struct {
+		Content string
+	}
+	taskName, err := q.Enqueue(ctx, &tasksbox.JsonPostTask{
+		RelativeURI: "https://example.com/handler",
+		Body: &Body{
+			Content: "Hello InMemoryQueue",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taskName != "" {
+		t.Errorf("unexpected taskName %q", taskName)
+	}
+
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotURL != "https://example.com/handler" {
+		t.Errorf("unexpected url %q", gotURL)
+	}
+	if len(gotBody) == 0 {
+		t.Error("body is empty")
+	}
+}
+
+func TestInMemoryQueue_Enqueue_ScheduledTime(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var order []string
+
+	q := tasksbox.NewInMemoryQueue(1, func(ctx context.Context, method, url string, headers map[string]string, body []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, url)
+		return nil
+	})
+
+	now := time.Now()
+	if _, err := q.Enqueue(ctx, &tasksbox.JsonPostTask{RelativeURI: "later", ScheduledTime: now.Add(100 * time.Millisecond)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(ctx, &tasksbox.JsonPostTask{RelativeURI: "sooner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "sooner" || order[1] != "later" {
+		t.Errorf("unexpected order %v", order)
+	}
+}
+
+func TestInMemoryQueue_Close_Timeout(t *testing.T) {
+	block := make(chan struct{})
+	q := tasksbox.NewInMemoryQueue(1, func(ctx context.Context, method, url string, headers map[string]string, body []byte) error {
+		<-block
+		return nil
+	})
+	defer close(block)
+
+	if _, err := q.EnqueueGet(context.Background(), &tasksbox.GetTask{RelativeURI: "https://example.com/slow"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Close(ctx); err == nil {
+		t.Error("expected Close to time out while a task is still in-flight")
+	}
+}
+
+func TestInMemoryQueue_WithOnError(t *testing.T) {
+	ctx := context.Background()
+	wantErr := errors.New("handler failed")
+
+	var mu sync.Mutex
+	var gotErr error
+	var gotMethod, gotURL string
+
+	q := tasksbox.NewInMemoryQueue(1, func(ctx context.Context, method, url string, headers map[string]string, body []byte) error {
+		return wantErr
+	}, tasksbox.WithOnError(func(err error, method, url string) {
+		mu.Lock()
+		defer mu.Unlock()
+		gotErr = err
+		gotMethod = method
+		gotURL = url
+	}))
+
+	if _, err := q.Enqueue(ctx, &tasksbox.JsonPostTask{RelativeURI: "https://example.com/handler"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !errors.Is(gotErr, wantErr) {
+		t.Errorf("unexpected error %v", gotErr)
+	}
+	if gotMethod != "POST" {
+		t.Errorf("unexpected method %q", gotMethod)
+	}
+	if gotURL != "https://example.com/handler" {
+		t.Errorf("unexpected url %q", gotURL)
+	}
+}
+
+func TestInMemoryQueue_Enqueue_WithDedupHash(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var calls int
+
+	q := tasksbox.NewInMemoryQueue(1, func(ctx context.Context, method, url string, headers map[string]string, body []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil
+	})
+
+	task := &tasksbox.JsonPostTask{RelativeURI: "https://example.com/handler"}
+	name1, err := q.Enqueue(ctx, task, tasksbox.WithDedupHash("2026-07-27"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name1 == "" {
+		t.Error("expected a dedup hash based taskName")
+	}
+
+	if _, err := q.Enqueue(ctx, task, tasksbox.WithDedupHash("2026-07-27")); err == nil {
+		t.Error("expected ErrAlreadyExists for a duplicate Enqueue() with the same dedup hash")
+	}
+
+	name2, err := q.Enqueue(ctx, task, tasksbox.WithDedupHash("2026-07-27"), tasksbox.WithIgnoreAlreadyExists())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name2 != name1 {
+		t.Errorf("unexpected taskName %q, want %q", name2, name1)
+	}
+
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 1 {
+		t.Errorf("unexpected calls %d, want 1", calls)
+	}
+}
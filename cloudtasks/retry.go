@@ -0,0 +1,105 @@
+package cloudtasks
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMaxConcurrency is WithMaxConcurrency() が指定されなかった場合に ...Multi 系のメソッドが同時に実行する Task 作成処理数
+const defaultMaxConcurrency = 10
+
+// RetryPolicy is ...Multi 系のメソッドが個々の Task 作成に失敗した時のリトライ方法を指定する
+// Unavailable, ResourceExhausted, DeadlineExceeded, Internal, Aborted 以外の Code は retry しない
+type RetryPolicy struct {
+	// MaxAttempts is 1回目の試行を含めた最大試行回数。1を指定すると retry しない
+	MaxAttempts int
+
+	// BaseDelay is exponential backoff の基準になる待ち時間。未指定の場合は 100ms
+	BaseDelay time.Duration
+
+	// MaxDelay is 待ち時間の上限。未指定の場合は 30s
+	MaxDelay time.Duration
+}
+
+// backoff is full jitter 版の exponential backoff
+// attempt はそれまでの試行回数 (1 始まり) で、sleep = rand(0, min(MaxDelay, BaseDelay*2^(attempt-1)))
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	d := base * time.Duration(int64(1)<<uint(attempt-1))
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// WithMaxConcurrency is CreateJsonPostTaskMulti, CreateGetTaskMulti などの ...Multi 系のメソッドが同時に実行する Task 作成処理数の上限を指定する
+// 未指定の場合は defaultMaxConcurrency を使う
+func WithMaxConcurrency(n int) CreateTaskOptions {
+	return func(o *createTaskOptions) {
+		o.maxConcurrency = n
+	}
+}
+
+// WithRetry is ...Multi 系のメソッドが retryable な gRPC Code を受け取った時にリトライする Policy を指定する
+func WithRetry(policy RetryPolicy) CreateTaskOptions {
+	return func(o *createTaskOptions) {
+		o.retryPolicy = &policy
+	}
+}
+
+func (o *createTaskOptions) maxConcurrencyOrDefault() int {
+	if o.maxConcurrency > 0 {
+		return o.maxConcurrency
+	}
+	return defaultMaxConcurrency
+}
+
+// isRetryableError is err (や %w で wrap されたその先) が retryable な gRPC Status Code かどうかを返す
+func isRetryableError(err error) bool {
+	for err != nil {
+		if gs, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+			switch gs.GRPCStatus().Code() {
+			case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Internal, codes.Aborted:
+				return true
+			}
+			return false
+		}
+		err = xerrors.Unwrap(err)
+	}
+	return false
+}
+
+// runWithRetry is fn を実行し、policy があり err が retryable な場合は backoff を挟んで再試行する
+// 戻り値の int は試行回数 (1回も retry しなければ 1)
+func runWithRetry(ctx context.Context, policy *RetryPolicy, fn func() (string, error)) (string, int, error) {
+	attempts := 0
+	for {
+		attempts++
+		name, err := fn()
+		if err == nil {
+			return name, attempts, nil
+		}
+		if policy == nil || attempts >= policy.MaxAttempts || !isRetryableError(err) {
+			return "", attempts, err
+		}
+		timer := time.NewTimer(policy.backoff(attempts))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", attempts, ctx.Err()
+		}
+	}
+}
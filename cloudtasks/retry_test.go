@@ -0,0 +1,53 @@
+package cloudtasks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tasksbox "github.com/sinmetalcraft/gcpbox/cloudtasks"
+)
+
+func TestService_CreateJsonPostTaskMulti_WithMaxConcurrencyAndRetry(t *testing.T) {
+	ctx := context.Background()
+
+	s := newService(t)
+
+	queue := &tasksbox.Queue{
+		ProjectID: "sinmetal-ci",
+		Region:    "asia-northeast1",
+		Name:      "gcpboxtest",
+	}
+	type Body struct {
+		Content string
+	}
+
+	const runHandlerURI = "https://gcpboxtest-73zry4yfvq-an.a.run.app/cloudtasks/run/json-post-task"
+	var tasks []*tasksbox.JsonPostTask
+	for i := 0; i < 20; i++ {
+		tasks = append(tasks, &tasksbox.JsonPostTask{
+			RelativeURI: runHandlerURI,
+			Deadline:    30 * time.Minute,
+			Body: &Body{
+				Content: "Hello CreateJsonPostTaskMulti With Concurrency Limit",
+			},
+		})
+	}
+
+	tns, err := s.CreateJsonPostTaskMulti(ctx, queue, tasks,
+		tasksbox.WithMaxConcurrency(3),
+		tasksbox.WithRetry(tasksbox.RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   100 * time.Millisecond,
+			MaxDelay:    2 * time.Second,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tn := range tns {
+		if len(tn) < 1 {
+			t.Errorf("%d : task name is empty", i)
+		}
+	}
+}
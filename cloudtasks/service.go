@@ -19,9 +19,11 @@ import (
 type Service struct {
 	taskClient          *cloudtasks.Client
 	serviceAccountEmail string
+	defaultAuth         AuthMode
 }
 
 // NewService is return Service
+// Task 個別に AuthMode を指定しない場合、従来通り OIDC Token を使う
 func NewService(ctx context.Context, taskClient *cloudtasks.Client, serviceAccountEmail string) (*Service, error) {
 	return &Service{
 		taskClient:          taskClient,
@@ -117,6 +119,20 @@ type JsonPostTask struct {
 	// 中で projects/{PROJECT_ID}/locations/{LOCATION}/queues/{QUEUE_ID}/tasks/{TASK_ID} 形式にしているので指定するのは {TASK_ID} の部分だけ
 	// 未指定の場合は自動的に設定される
 	Name string
+
+	// DedupBy is WithDedupHash() と一緒に使う
+	// 指定すると Body の代わりにこちらを hash の対象にする
+	// Body に時刻などの nonce を含めていて、そのままでは重複排除のための hash に使えない場合に使う
+	// optional
+	DedupBy interface{}
+
+	// Auth is この Task を投げる時に使う認証方式
+	// optional。未指定 (AuthUnspecified) の場合は Service の defaultAuth に従う
+	Auth AuthMode
+
+	// OAuth2Token is Auth に AuthOAuth2 を指定した場合の Service Account / Scope
+	// optional。未指定の場合は Service に設定されている Service Account Email と cloud-platform Scope を使う
+	OAuth2Token *OAuth2TokenConfig
 }
 
 // CreateJsonPostTask is BodyにJsonを入れるTaskを作る
@@ -125,18 +141,31 @@ func (s *Service) CreateJsonPostTask(ctx context.Context, queue *Queue, task *Js
 	if err != nil {
 		return "", xerrors.Errorf("failed json.Marshal(). body=%+v : %w", task.Body, err)
 	}
-	got, err := s.CreateTask(ctx, queue, task.Name, &taskspb.HttpRequest{
+
+	taskName := task.Name
+	if len(taskName) == 0 {
+		opt := createTaskOptions{}
+		for _, o := range ops {
+			o(&opt)
+		}
+		if opt.dedupHashEnabled {
+			payload, err := dedupPayload(task.DedupBy, body)
+			if err != nil {
+				return "", xerrors.Errorf("failed dedupPayload(). queue=%+v : %w", queue, err)
+			}
+			taskName = dedupTaskName(queue.Parent(), taskspb.HttpMethod_POST.String(), task.RelativeURI, payload, opt.dedupHashBucket)
+		}
+	}
+
+	req := &taskspb.HttpRequest{
 		Url:        task.RelativeURI,
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		HttpMethod: taskspb.HttpMethod_POST,
 		Body:       body,
-		AuthorizationHeader: &taskspb.HttpRequest_OidcToken{
-			OidcToken: &taskspb.OidcToken{
-				ServiceAccountEmail: s.serviceAccountEmail,
-				Audience:            task.Audience,
-			},
-		},
-	}, task.ScheduledTime, task.Deadline, ops...)
+	}
+	s.setAuthorizationHeader(req, task.Auth, task.Audience, task.OAuth2Token)
+
+	got, err := s.CreateTask(ctx, queue, taskName, req, task.ScheduledTime, task.Deadline, ops...)
 	if err != nil {
 		return "", xerrors.Errorf("failed CreateJsonPostTask(). queue=%+v, body=%+v : %w", queue, task.Body, err)
 	}
@@ -144,23 +173,36 @@ func (s *Service) CreateJsonPostTask(ctx context.Context, queue *Queue, task *Js
 }
 
 // CreateJsonPostTaskMulti is Queue に JsonPostTask を複数作成する
+// WithMaxConcurrency() で同時実行数を、WithRetry() で retryable な Code を受け取った時の再試行方法を指定できる
 func (s *Service) CreateJsonPostTaskMulti(ctx context.Context, queue *Queue, tasks []*JsonPostTask, ops ...CreateTaskOptions) ([]string, error) {
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
 	results := make([]string, len(tasks))
 	merr := MultiError{}
+	sem := make(chan struct{}, opt.maxConcurrencyOrDefault())
 	wg := &sync.WaitGroup{}
 	for i, task := range tasks {
 		wg.Add(1)
 		go func(i int, task *JsonPostTask) {
 			defer wg.Done()
-			tn, err := s.CreateJsonPostTask(ctx, queue, task, ops...)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			tn, attempts, err := runWithRetry(ctx, opt.retryPolicy, func() (string, error) {
+				return s.CreateJsonPostTask(ctx, queue, task, ops...)
+			})
 			if err != nil {
 				appErr := &Error{}
 				if xerrors.As(err, &appErr) && appErr.Code == ErrAlreadyExists.Code {
 					appErr.KV["index"] = i
+					appErr.KV["attempts"] = attempts
 					merr.Append(appErr)
 					return
 				}
-				merr.Append(NewErrCreateMultiTask("failed CreateJsonPostTask", map[string]interface{}{"index": i, "taskName": task.Name, "URI": task.RelativeURI}, err))
+				merr.Append(NewErrCreateMultiTask("failed CreateJsonPostTask", map[string]interface{}{"index": i, "taskName": task.Name, "URI": task.RelativeURI, "attempts": attempts}, err))
 			}
 			results[i] = tn
 		}(i, task)
@@ -198,21 +240,46 @@ type GetTask struct {
 	// 中で projects/{PROJECT_ID}/locations/{LOCATION}/queues/{QUEUE_ID}/tasks/{TASK_ID} 形式にしているので指定するのは {TASK_ID} の部分だけ
 	// 未指定の場合は自動的に設定される
 	Name string
+
+	// DedupBy is WithDedupHash() と一緒に使う
+	// 指定すると URL に加えてこちらも hash の対象にする。Headers は hash の対象にならない
+	// optional
+	DedupBy interface{}
+
+	// Auth is この Task を投げる時に使う認証方式
+	// optional。未指定 (AuthUnspecified) の場合は Service の defaultAuth に従う
+	Auth AuthMode
+
+	// OAuth2Token is Auth に AuthOAuth2 を指定した場合の Service Account / Scope
+	// optional。未指定の場合は Service に設定されている Service Account Email と cloud-platform Scope を使う
+	OAuth2Token *OAuth2TokenConfig
 }
 
 // CreateGetTask is Get Request 用の Task を作る
 func (s *Service) CreateGetTask(ctx context.Context, queue *Queue, task *GetTask, ops ...CreateTaskOptions) (string, error) {
-	got, err := s.CreateTask(ctx, queue, task.Name, &taskspb.HttpRequest{
+	taskName := task.Name
+	if len(taskName) == 0 {
+		opt := createTaskOptions{}
+		for _, o := range ops {
+			o(&opt)
+		}
+		if opt.dedupHashEnabled {
+			payload, err := dedupPayload(task.DedupBy, nil)
+			if err != nil {
+				return "", xerrors.Errorf("failed dedupPayload(). queue=%+v : %w", queue, err)
+			}
+			taskName = dedupTaskName(queue.Parent(), taskspb.HttpMethod_GET.String(), task.RelativeURI, payload, opt.dedupHashBucket)
+		}
+	}
+
+	req := &taskspb.HttpRequest{
 		Url:        task.RelativeURI,
 		Headers:    task.Headers,
 		HttpMethod: taskspb.HttpMethod_GET,
-		AuthorizationHeader: &taskspb.HttpRequest_OidcToken{
-			OidcToken: &taskspb.OidcToken{
-				ServiceAccountEmail: s.serviceAccountEmail,
-				Audience:            task.Audience,
-			},
-		},
-	}, task.ScheduledTime, task.Deadline, ops...)
+	}
+	s.setAuthorizationHeader(req, task.Auth, task.Audience, task.OAuth2Token)
+
+	got, err := s.CreateTask(ctx, queue, taskName, req, task.ScheduledTime, task.Deadline, ops...)
 	if err != nil {
 		return "", xerrors.Errorf("failed CreateJsonPostTask(). queue=%+v, url=%s : %w", queue, task.RelativeURI, err)
 	}
@@ -220,23 +287,36 @@ func (s *Service) CreateGetTask(ctx context.Context, queue *Queue, task *GetTask
 }
 
 // CreateGetTaskMulti is Queue に GetTask を作成する
+// WithMaxConcurrency() で同時実行数を、WithRetry() で retryable な Code を受け取った時の再試行方法を指定できる
 func (s *Service) CreateGetTaskMulti(ctx context.Context, queue *Queue, tasks []*GetTask, ops ...CreateTaskOptions) ([]string, error) {
+	opt := createTaskOptions{}
+	for _, o := range ops {
+		o(&opt)
+	}
+
 	results := make([]string, len(tasks))
 	merr := MultiError{}
+	sem := make(chan struct{}, opt.maxConcurrencyOrDefault())
 	wg := &sync.WaitGroup{}
 	for i, task := range tasks {
 		wg.Add(1)
 		go func(i int, task *GetTask) {
 			defer wg.Done()
-			tn, err := s.CreateGetTask(ctx, queue, task, ops...)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			tn, attempts, err := runWithRetry(ctx, opt.retryPolicy, func() (string, error) {
+				return s.CreateGetTask(ctx, queue, task, ops...)
+			})
 			if err != nil {
 				appErr := &Error{}
 				if xerrors.As(err, &appErr) && appErr.Code == ErrAlreadyExists.Code {
 					appErr.KV["index"] = i
+					appErr.KV["attempts"] = attempts
 					merr.Append(appErr)
 					return
 				}
-				merr.Append(NewErrCreateMultiTask("failed CreateGetTask", map[string]interface{}{"index": i, "taskName": task.Name, "URI": task.RelativeURI}, err))
+				merr.Append(NewErrCreateMultiTask("failed CreateGetTask", map[string]interface{}{"index": i, "taskName": task.Name, "URI": task.RelativeURI, "attempts": attempts}, err))
 			}
 			results[i] = tn
 		}(i, task)